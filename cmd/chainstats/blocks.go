@@ -0,0 +1,392 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/spf13/cobra"
+
+	"github.com/ClaudeZsb/toolkit/internal/client"
+	"github.com/ClaudeZsb/toolkit/internal/output"
+)
+
+func newBlocksCmd() *cobra.Command {
+	var (
+		blockCount    int
+		fetchInterval int
+		batchSize     int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "blocks",
+		Short: "Fetch recent block gas usage and timestamps to a CSV or JSONL file",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := cmd.Flags().GetString("output-format")
+			if err != nil {
+				return err
+			}
+			outputFormat, err := output.ParseFormat(format)
+			if err != nil {
+				return err
+			}
+
+			datadir, err := cmd.Flags().GetString("datadir")
+			if err != nil {
+				return err
+			}
+			if datadir != "" {
+				return runBlocksLocal(datadir, blockCount, outputFormat)
+			}
+
+			rpcURL, err := cmd.Flags().GetString("rpc")
+			if err != nil {
+				return err
+			}
+			concurrency, err := cmd.Flags().GetInt("concurrency")
+			if err != nil {
+				return err
+			}
+			retries, err := cmd.Flags().GetInt("retries")
+			if err != nil {
+				return err
+			}
+			backoff, err := cmd.Flags().GetDuration("backoff")
+			if err != nil {
+				return err
+			}
+			return runBlocks(rpcURL, blockCount, fetchInterval, concurrency, batchSize, retries, backoff, outputFormat)
+		},
+	}
+
+	cmd.Flags().IntVar(&blockCount, "block-count", envOrDefaultInt("BLOCK_COUNT", 100), "number of recent blocks to fetch")
+	cmd.Flags().IntVar(&fetchInterval, "fetch-interval", envOrDefaultInt("FETCH_INTERVAL", 100), "milliseconds to wait for a batch to fill before flushing it early")
+	cmd.Flags().IntVar(&batchSize, "batch-size", envOrDefaultInt("BATCH_SIZE", 25), "number of blocks packed into each JSON-RPC batch request")
+
+	return cmd
+}
+
+// runBlocksLocal scans blockCount blocks directly out of a local chaindata
+// database, streaming rows to the output file as it goes so memory stays flat even when
+// scanning millions of blocks.
+func runBlocksLocal(datadir string, blockCount int, format output.Format) error {
+	localClient, err := client.New(datadir)
+	if err != nil {
+		return fmt.Errorf("failed to open chaindata at %s: %w", datadir, err)
+	}
+	defer localClient.Close()
+
+	ctx := context.Background()
+	head, err := localClient.BlockByNumber(ctx, nil)
+	if err != nil || head == nil {
+		return fmt.Errorf("failed to read head block from %s: %w", datadir, err)
+	}
+	latestBlockNumber := head.NumberU64()
+
+	var startBlockNumber uint64
+	if latestBlockNumber >= uint64(blockCount-1) {
+		startBlockNumber = latestBlockNumber - uint64(blockCount-1)
+	}
+
+	slog.Info("Scanning blocks directly from chaindata", "start", startBlockNumber, "end", latestBlockNumber, "datadir", datadir)
+
+	filename := fmt.Sprintf("block_history_%s.%s", time.Now().Format("2006-01-02_15-04-05"), format.Ext())
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer file.Close()
+
+	columns := []string{"Block Number", "Gas Used", "Gas Limit", "Gas Utilization %", "Timestamp", "Block Hash"}
+	writer, err := output.NewRowWriter(file, format, columns, true)
+	if err != nil {
+		return err
+	}
+	defer writer.Flush()
+
+	blocksWritten := 0
+	for blockNum := startBlockNumber; blockNum <= latestBlockNumber; blockNum++ {
+		block, err := localClient.BlockByNumber(ctx, new(big.Int).SetUint64(blockNum))
+		if err != nil || block == nil {
+			slog.Warn("Failed to read block", "block", blockNum, "error", err)
+			continue
+		}
+
+		data := client.BlockDataFromBlock(block)
+		gasUtilization := float64(data.GasUsed) / float64(data.GasLimit) * 100
+		row := []string{
+			strconv.FormatUint(data.Number, 10),
+			strconv.FormatUint(data.GasUsed, 10),
+			strconv.FormatUint(data.GasLimit, 10),
+			fmt.Sprintf("%.2f", gasUtilization),
+			strconv.FormatUint(data.Timestamp, 10),
+			data.Hash,
+		}
+		if err := writer.WriteRow(row); err != nil {
+			slog.Warn("Failed to write row for block", "block", blockNum, "error", err)
+			continue
+		}
+		blocksWritten++
+
+		if blockNum%100000 == 0 {
+			writer.Flush()
+			slog.Info("Wrote block", "block", blockNum, "written", blocksWritten)
+		}
+	}
+
+	slog.Info("Successfully wrote blocks", "count", blocksWritten, "file", filename)
+	return nil
+}
+
+// fetchBatch resolves a batch of block numbers, splitting it in half and
+// retrying on failure so that one bad or rate-limited block doesn't poison
+// the whole batch. Once a batch is down to a single block, it falls back to
+// the old per-block retry semantics. A batch response can itself carry
+// per-block errors (GetBlocksBatch only fails transport-wise as a whole), so
+// those are retried too via retryFailedResults instead of being returned
+// on the first pass.
+func fetchBatch(rpcURL string, blockNumbers []uint64, retriesLeft int, backoff time.Duration) []client.BlockResult {
+	if len(blockNumbers) == 1 {
+		return []client.BlockResult{fetchSingleWithRetry(rpcURL, blockNumbers[0], retriesLeft+1, backoff)}
+	}
+
+	results, err := client.GetBlocksBatch(rpcURL, blockNumbers)
+	if err != nil {
+		if retriesLeft <= 0 {
+			out := make([]client.BlockResult, len(blockNumbers))
+			for i, bn := range blockNumbers {
+				out[i] = client.BlockResult{BlockNumber: bn, Error: err}
+			}
+			return out
+		}
+
+		mid := len(blockNumbers) / 2
+		left := fetchBatch(rpcURL, blockNumbers[:mid], retriesLeft-1, backoff)
+		right := fetchBatch(rpcURL, blockNumbers[mid:], retriesLeft-1, backoff)
+		return append(left, right...)
+	}
+
+	return retryFailedResults(rpcURL, results, retriesLeft, backoff)
+}
+
+// retryFailedResults re-fetches whichever blocks in results came back with a
+// per-block error (a soft "rpc error: ..." or "block not found" from an
+// otherwise-successful batch response), so those blocks get the same
+// retry/split treatment as a transport-level batch failure.
+func retryFailedResults(rpcURL string, results []client.BlockResult, retriesLeft int, backoff time.Duration) []client.BlockResult {
+	if retriesLeft <= 0 {
+		return results
+	}
+
+	var failed []uint64
+	for _, r := range results {
+		if r.Error != nil {
+			failed = append(failed, r.BlockNumber)
+		}
+	}
+	if len(failed) == 0 {
+		return results
+	}
+
+	retried := fetchBatch(rpcURL, failed, retriesLeft-1, backoff)
+	retriedByBlock := make(map[uint64]client.BlockResult, len(retried))
+	for _, r := range retried {
+		retriedByBlock[r.BlockNumber] = r
+	}
+
+	out := make([]client.BlockResult, len(results))
+	for i, r := range results {
+		if updated, ok := retriedByBlock[r.BlockNumber]; ok {
+			out[i] = updated
+			continue
+		}
+		out[i] = r
+	}
+	return out
+}
+
+func fetchSingleWithRetry(rpcURL string, blockNumber uint64, maxRetries int, backoff time.Duration) client.BlockResult {
+	var blockData *client.BlockData
+	var err error
+
+	delay := backoff
+	for retry := 0; retry < maxRetries; retry++ {
+		blockData, err = client.GetBlockInfo(rpcURL, blockNumber)
+		if err == nil {
+			break
+		}
+		if retry < maxRetries-1 {
+			time.Sleep(delay)
+			delay *= 2
+		}
+	}
+
+	return client.BlockResult{BlockNumber: blockNumber, Data: blockData, Error: err}
+}
+
+func runBlocks(rpcURL string, blockCount, fetchInterval, concurrency, batchSize, retries int, backoff time.Duration, format output.Format) error {
+	slog.Info("Will fetch blocks", "count", blockCount, "batch_size", batchSize, "flush_interval_ms", fetchInterval, "concurrency", concurrency)
+
+	ethClient, err := ethclient.Dial(rpcURL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to the Ethereum client: %w", err)
+	}
+	defer ethClient.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	chainID, err := ethClient.ChainID(ctx)
+	if err != nil {
+		slog.Warn("Failed to get chain ID", "error", err)
+	} else {
+		slog.Info("Connected to Ethereum network", "chain_id", chainID)
+	}
+
+	var latestBlockNumber uint64
+	delay := backoff
+	for i := 0; i <= retries; i++ {
+		latestBlockNumber, err = ethClient.BlockNumber(ctx)
+		if err != nil {
+			slog.Warn("Failed to get latest block number", "attempt", i+1, "error", err)
+			if i == retries {
+				return fmt.Errorf("failed to get latest block number after %d attempts: %w", retries+1, err)
+			}
+			time.Sleep(delay)
+			delay *= 2
+			continue
+		}
+		break
+	}
+
+	slog.Info("Latest block number", "block", latestBlockNumber)
+
+	var startBlockNumber uint64
+	if latestBlockNumber >= uint64(blockCount-1) {
+		startBlockNumber = latestBlockNumber - uint64(blockCount-1)
+	} else {
+		startBlockNumber = 0
+	}
+
+	slog.Info("Fetching blocks", "start", startBlockNumber, "end", latestBlockNumber)
+
+	filename := fmt.Sprintf("block_history_%s.%s", time.Now().Format("2006-01-02_15-04-05"), format.Ext())
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer file.Close()
+
+	columns := []string{"Block Number", "Gas Used", "Gas Limit", "Gas Utilization %", "Timestamp", "Block Hash"}
+	writer, err := output.NewRowWriter(file, format, columns, true)
+	if err != nil {
+		return err
+	}
+	defer writer.Flush()
+
+	resultChan := make(chan client.BlockResult, blockCount)
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, concurrency)
+
+	dispatch := func(batch []uint64) {
+		semaphore <- struct{}{}
+		wg.Add(1)
+		go func(bn []uint64) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			for _, result := range fetchBatch(rpcURL, bn, retries, backoff) {
+				resultChan <- result
+			}
+		}(batch)
+	}
+
+	flushInterval := time.Duration(fetchInterval) * time.Millisecond
+	batch := make([]uint64, 0, batchSize)
+	lastFlush := time.Now()
+
+	for blockNum := startBlockNumber; blockNum <= latestBlockNumber; blockNum++ {
+		batch = append(batch, blockNum)
+
+		if len(batch) >= batchSize || (flushInterval > 0 && time.Since(lastFlush) >= flushInterval) {
+			dispatch(batch)
+			batch = make([]uint64, 0, batchSize)
+			lastFlush = time.Now()
+		}
+		if blockNum%100 == 0 {
+			slog.Info("Queued block", "block", blockNum)
+		}
+	}
+	if len(batch) > 0 {
+		dispatch(batch)
+	}
+
+	go func() {
+		wg.Wait()
+		close(resultChan)
+	}()
+
+	// Results arrive out of order (batches race each other), so stream them
+	// to the output file in block order as soon as each contiguous prefix is available,
+	// instead of buffering the whole range in memory.
+	blocksFetched := 0
+	blocksFailed := 0
+	pending := make(map[uint64]client.BlockResult)
+	nextToWrite := startBlockNumber
+
+	writeResult := func(blockNum uint64, result client.BlockResult) {
+		if result.Error != nil {
+			slog.Warn("Failed to get block", "block", blockNum, "error", result.Error)
+			blocksFailed++
+
+			row := []string{strconv.FormatUint(blockNum, 10), "ERROR", "ERROR", "ERROR", "ERROR", "ERROR"}
+			if writeErr := writer.WriteRow(row); writeErr != nil {
+				slog.Warn("Failed to write error row for block", "block", blockNum, "error", writeErr)
+			}
+			return
+		}
+
+		gasUtilization := float64(result.Data.GasUsed) / float64(result.Data.GasLimit) * 100
+		row := []string{
+			strconv.FormatUint(result.Data.Number, 10),
+			strconv.FormatUint(result.Data.GasUsed, 10),
+			strconv.FormatUint(result.Data.GasLimit, 10),
+			fmt.Sprintf("%.2f", gasUtilization),
+			strconv.FormatUint(result.Data.Timestamp, 10),
+			result.Data.Hash,
+		}
+		if err := writer.WriteRow(row); err != nil {
+			slog.Warn("Failed to write row for block", "block", blockNum, "error", err)
+		} else {
+			blocksFetched++
+		}
+
+		if blockNum%100 == 0 {
+			slog.Info("Wrote block", "block", blockNum, "fetched", blocksFetched, "failed", blocksFailed)
+		}
+	}
+
+	for result := range resultChan {
+		pending[result.BlockNumber] = result
+		for {
+			result, ok := pending[nextToWrite]
+			if !ok {
+				break
+			}
+			writeResult(nextToWrite, result)
+			delete(pending, nextToWrite)
+			nextToWrite++
+		}
+	}
+	for ; nextToWrite <= latestBlockNumber; nextToWrite++ {
+		slog.Warn("Missing result for block", "block", nextToWrite)
+	}
+
+	slog.Info("Successfully wrote blocks", "fetched", blocksFetched, "file", filename, "failed", blocksFailed)
+	return nil
+}
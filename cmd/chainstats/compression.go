@@ -0,0 +1,588 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/spf13/cobra"
+
+	"github.com/ClaudeZsb/toolkit/internal/client"
+	"github.com/ClaudeZsb/toolkit/internal/compress"
+	"github.com/ClaudeZsb/toolkit/internal/feemath"
+)
+
+// txResult holds the compression estimation results for a transaction, or
+// (when EndOfBlock is set) marks that a block's results are all enqueued
+// with no tx fields populated. The writer stage uses the marker to know
+// precisely which block's bytes have been durably written, since blocks with
+// no transactions (or that failed outright) would otherwise never appear in
+// resultChan at all.
+type txResult struct {
+	EndOfBlock bool
+
+	BlockNumber    uint64
+	Best           uint32
+	Fastlz         uint32
+	Zeroes         uint32
+	NonZeroes      uint32
+	BlobFill       uint32 // cumulative fill of the current blob after adding Best's compressed bytes
+	BlobCrossings  uint32 // number of blob boundaries crossed while packing this tx
+	BlobBaseFeeWei uint64 // blob base fee at the source block
+}
+
+// blockJob represents a block to be processed. Number is always populated so
+// the reorder stage can sequence jobs even when a fetch permanently fails.
+// Header is populated on success; Block only carries a transaction list when
+// the header indicated the block wasn't empty, so empty blocks skip the body
+// fetch entirely. Failed marks a block whose header or body fetch exhausted
+// its retries, so the estimator stage skips it instead of treating a nil
+// Block as "empty".
+type blockJob struct {
+	Number uint64
+	Header *types.Header
+	Block  *types.Block
+	Failed bool
+}
+
+// compressionCheckpoint is the sidecar file persisted every
+// checkpoint-interval blocks (and on SIGINT) so an interrupted scan resumes
+// with warmed estimators rebuilt from the saved state instead of starting over. OutputOffset is
+// the exact byte length of outputPath as of LastCompletedBlock, so resume can
+// truncate away anything written after that point instead of re-appending
+// duplicate rows for blocks that get reprocessed.
+type compressionCheckpoint struct {
+	LastCompletedBlock uint64 `json:"last_completed_block"`
+	OutputOffset       int64  `json:"output_offset"`
+	BootstrapDone      bool   `json:"bootstrap_done"`
+	BootstrapCount     int    `json:"bootstrap_count"`
+	EstimatorHistory   []byte `json:"estimator_history"`
+	BlobTrackerFill    uint32 `json:"blob_tracker_fill"`
+}
+
+func loadCheckpoint(path string) (*compressionCheckpoint, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var cp compressionCheckpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, err
+	}
+	return &cp, nil
+}
+
+// writeCheckpoint fsyncs the checkpoint to a temp file and renames it into
+// place, so a crash mid-write never leaves a truncated or corrupt sidecar.
+func writeCheckpoint(path string, cp *compressionCheckpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	tmpPath := path + ".tmp"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// estimatorState holds the mutable, checkpointable state of the estimator
+// stage behind a mutex, so the SIGINT handler can snapshot it safely while
+// the estimator worker keeps mutating it concurrently.
+type estimatorState struct {
+	mu sync.Mutex
+
+	estimator   *compress.ZlibBatchEstimator
+	blobTracker *compress.BlobFillTracker
+
+	bootstrapCount int
+	bootstrapDone  bool
+}
+
+// checkpointAt builds a checkpoint for block/offset (the highest block
+// number and output byte length the caller has confirmed were durably
+// written), combined with the estimator's current warm state. Callers must
+// supply block/offset themselves rather than reading an estimatorState field
+// because only the writer goroutine actually knows what's hit disk; the
+// estimator stage runs ahead of it by up to resultChan's buffer.
+func (s *estimatorState) checkpointAt(block uint64, offset int64) *compressionCheckpoint {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &compressionCheckpoint{
+		LastCompletedBlock: block,
+		OutputOffset:       offset,
+		BootstrapDone:      s.bootstrapDone,
+		BootstrapCount:     s.bootstrapCount,
+		EstimatorHistory:   s.estimator.Checkpoint(),
+		BlobTrackerFill:    s.blobTracker.Checkpoint(),
+	}
+}
+
+// writerProgress tracks the highest block number and output-file byte offset
+// the writer goroutine has confirmed are durably written, so the SIGINT
+// handler and the final post-drain checkpoint can checkpoint the same
+// ground truth the writer uses for its periodic checkpoints.
+type writerProgress struct {
+	mu     sync.Mutex
+	block  uint64
+	offset int64
+}
+
+func (p *writerProgress) update(block uint64, offset int64) {
+	p.mu.Lock()
+	p.block, p.offset = block, offset
+	p.mu.Unlock()
+}
+
+func (p *writerProgress) get() (block uint64, offset int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.block, p.offset
+}
+
+func newCompressionCmd() *cobra.Command {
+	var (
+		outputPath         string
+		endBlock           uint64
+		startBlock         int64
+		bootstrapTxs       int
+		trimSignature      bool
+		checkpointPath     string
+		checkpointInterval uint64
+	)
+
+	cmd := &cobra.Command{
+		Use:   "compression",
+		Short: "Estimate per-transaction L1 calldata compression costs over a block range",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if checkpointPath == "" {
+				checkpointPath = outputPath + ".checkpoint.json"
+			}
+
+			datadir, err := cmd.Flags().GetString("datadir")
+			if err != nil {
+				return err
+			}
+			rpcURL, err := cmd.Flags().GetString("rpc")
+			if err != nil {
+				return err
+			}
+			location := rpcURL
+			if datadir != "" {
+				location = datadir
+			}
+
+			concurrency, err := cmd.Flags().GetInt("concurrency")
+			if err != nil {
+				return err
+			}
+			retries, err := cmd.Flags().GetInt("retries")
+			if err != nil {
+				return err
+			}
+			backoff, err := cmd.Flags().GetDuration("backoff")
+			if err != nil {
+				return err
+			}
+
+			return runCompression(location, outputPath, endBlock, startBlock, bootstrapTxs, trimSignature, concurrency, retries, backoff, checkpointPath, checkpointInterval)
+		},
+	}
+
+	cmd.Flags().StringVar(&outputPath, "output", "./data/fastlz.bin", "path to write the binary result stream to")
+	cmd.Flags().Uint64Var(&endBlock, "end-block", 78980000, "block number to stop scanning at (exclusive, scanning descends toward it)")
+	cmd.Flags().Int64Var(&startBlock, "start-block", -1, "block number to start scanning from, -1 for latest (ignored when resuming from a checkpoint that has a completed block)")
+	cmd.Flags().IntVar(&bootstrapTxs, "bootstrap-txs", 1000, "number of leading transactions used to warm up the estimator without being recorded")
+	cmd.Flags().BoolVar(&trimSignature, "trim-signature", false, "strip the trailing 68-byte signature before estimating")
+	cmd.Flags().StringVar(&checkpointPath, "checkpoint", "", "path to the resume checkpoint file (default: <output>.checkpoint.json)")
+	cmd.Flags().Uint64Var(&checkpointInterval, "checkpoint-interval", 1000, "blocks between checkpoint writes")
+
+	return cmd
+}
+
+func runCompression(location, outputPath string, endBlock uint64, startBlock int64, bootstrapTxs int, trimSignature bool, concurrency, retries int, backoff time.Duration, checkpointPath string, checkpointInterval uint64) error {
+	cp, err := loadCheckpoint(checkpointPath)
+	if err != nil {
+		return err
+	}
+
+	outFileFlags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if cp != nil {
+		outFileFlags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	outFile, err := os.OpenFile(outputPath, outFileFlags, 0644)
+	if err != nil {
+		return err
+	}
+	defer outFile.Close()
+
+	state := &estimatorState{}
+	progress := &writerProgress{}
+	var outputOffset int64
+	if cp != nil {
+		slog.Info("Resuming from checkpoint", "checkpoint", checkpointPath, "last_completed_block", cp.LastCompletedBlock, "output_offset", cp.OutputOffset)
+		// outFile is opened O_APPEND above, so every write lands at EOF
+		// regardless of seek position; truncating here discards whatever was
+		// written past the last confirmed checkpoint (records from blocks
+		// that get reprocessed below) so resume never re-appends duplicates.
+		if err := outFile.Truncate(cp.OutputOffset); err != nil {
+			return fmt.Errorf("failed to truncate output to checkpointed offset: %w", err)
+		}
+		outputOffset = cp.OutputOffset
+		state.estimator = compress.RestoreZlibBatchEstimator(cp.EstimatorHistory)
+		state.blobTracker = compress.RestoreBlobFillTracker(cp.BlobTrackerFill)
+		state.bootstrapDone = cp.BootstrapDone
+		state.bootstrapCount = cp.BootstrapCount
+		progress.update(cp.LastCompletedBlock, cp.OutputOffset)
+		if cp.LastCompletedBlock > 0 {
+			startBlock = int64(cp.LastCompletedBlock) - 1
+		} else {
+			slog.Warn("Checkpoint has no completed blocks yet, using --start-block as-is")
+		}
+	} else {
+		state.estimator = compress.NewZlibBatchEstimator()
+		state.blobTracker = &compress.BlobFillTracker{}
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt)
+	go func() {
+		<-sigChan
+		slog.Warn("Received interrupt, writing final checkpoint")
+		block, offset := progress.get()
+		if err := writeCheckpoint(checkpointPath, state.checkpointAt(block, offset)); err != nil {
+			slog.Error("Failed to write checkpoint on interrupt", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("Checkpoint written, exiting")
+		os.Exit(130)
+	}()
+
+	slog.Info("Starting block fetcher", "end_block", endBlock)
+
+	jobChan := make(chan blockJob, 20)
+	orderedJobChan := make(chan blockJob, 20)
+	resultChan := make(chan txResult, 1000)
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		for job := range orderedJobChan {
+			if job.Failed {
+				slog.Warn("Worker: skipping block that exhausted retries, no data recorded", "block", job.Number)
+				resultChan <- txResult{EndOfBlock: true, BlockNumber: job.Number}
+				continue
+			}
+
+			block := job.Block
+			header := job.Header
+
+			var blobBaseFeeWei uint64
+			if excess := header.ExcessBlobGas; excess != nil {
+				blobBaseFeeWei = feemath.CalcBlobBaseFee(*excess).Uint64()
+			}
+
+			if block != nil {
+				for _, tx := range block.Transactions() {
+					if tx.Type() == types.DepositTxType {
+						continue
+					}
+					b, err := tx.MarshalBinary()
+					if err != nil {
+						slog.Warn("Worker: error marshaling tx", "error", err)
+						continue
+					}
+					if trimSignature && len(b) >= 68 {
+						b = b[:len(b)-68]
+					}
+
+					state.mu.Lock()
+					if !state.bootstrapDone {
+						state.estimator.Write(b)
+						state.bootstrapCount++
+						if state.bootstrapCount >= bootstrapTxs {
+							state.bootstrapDone = true
+							slog.Info("Bootstrap complete", "transactions", state.bootstrapCount)
+						}
+						state.mu.Unlock()
+						continue
+					}
+					best := state.estimator.Write(b)
+					blobFill, blobCrossings := state.blobTracker.Add(best)
+					state.mu.Unlock()
+
+					fastlz := compress.FlzCompressLen(b)
+					zeroes := uint32(0)
+					nonZeroes := uint32(0)
+					for _, by := range b {
+						if by == 0 {
+							zeroes++
+						} else {
+							nonZeroes++
+						}
+					}
+
+					resultChan <- txResult{
+						BlockNumber:    header.Number.Uint64(),
+						Best:           best,
+						Fastlz:         fastlz,
+						Zeroes:         zeroes,
+						NonZeroes:      nonZeroes,
+						BlobFill:       blobFill,
+						BlobCrossings:  uint32(blobCrossings),
+						BlobBaseFeeWei: blobBaseFeeWei,
+					}
+				}
+			}
+
+			resultChan <- txResult{EndOfBlock: true, BlockNumber: job.Number}
+		}
+	}()
+
+	// txResultBinarySize is the byte length of one record written below:
+	// seven uint32 fields plus one uint64 field.
+	const txResultBinarySize = 7*4 + 8
+
+	writerDone := make(chan bool)
+	go func() {
+		lastPrint := time.Now()
+		printInterval := 10 * time.Second
+		resultCount := 0
+		offset := outputOffset
+
+		for result := range resultChan {
+			if result.EndOfBlock {
+				// This block's records (zero or more) are now fully written,
+				// so offset is exactly how far to truncate on a future
+				// resume; only the writer goroutine can know this, since the
+				// estimator stage runs ahead of it by up to resultChan's buffer.
+				progress.update(result.BlockNumber, offset)
+				if checkpointInterval > 0 && result.BlockNumber%checkpointInterval == 0 {
+					if err := writeCheckpoint(checkpointPath, state.checkpointAt(result.BlockNumber, offset)); err != nil {
+						slog.Error("Failed to write checkpoint", "error", err)
+					} else {
+						slog.Info("Checkpoint written", "block", result.BlockNumber, "output_offset", offset)
+					}
+				}
+				continue
+			}
+
+			binary.Write(outFile, binary.LittleEndian, uint32(result.BlockNumber))
+			binary.Write(outFile, binary.LittleEndian, result.Best)
+			binary.Write(outFile, binary.LittleEndian, result.Fastlz)
+			binary.Write(outFile, binary.LittleEndian, result.Zeroes)
+			binary.Write(outFile, binary.LittleEndian, result.NonZeroes)
+			binary.Write(outFile, binary.LittleEndian, result.BlobFill)
+			binary.Write(outFile, binary.LittleEndian, result.BlobCrossings)
+			binary.Write(outFile, binary.LittleEndian, result.BlobBaseFeeWei)
+			offset += txResultBinarySize
+
+			resultCount++
+			if time.Since(lastPrint) > printInterval {
+				slog.Info("Processed transactions", "count", resultCount, "block", result.BlockNumber)
+				lastPrint = time.Now()
+			}
+		}
+		writerDone <- true
+	}()
+
+	// Reorder stage: header/body jobs complete out of order across the
+	// fetcher pool, but the estimator stage must consume them in strict
+	// descending order for its rotating zlib state (and therefore its
+	// checkpoint) to mean anything. Every block number dispatched to
+	// blockNumChan is guaranteed exactly one job here, success or failure
+	// (see the fetcher loop below), so next always advances and a run of
+	// RPC failures can never stall the sequence or silently drop blocks.
+	startBlockNumChan := make(chan uint64, 1)
+	go func() {
+		next := <-startBlockNumChan
+		pending := make(map[uint64]blockJob)
+
+		for job := range jobChan {
+			num := job.Number
+			pending[num] = job
+			for {
+				j, ok := pending[next]
+				if !ok {
+					break
+				}
+				orderedJobChan <- j
+				delete(pending, next)
+				if next == 0 {
+					break
+				}
+				next--
+			}
+		}
+		close(orderedJobChan)
+	}()
+
+	fetcherWg := &sync.WaitGroup{}
+	blockNumChan := make(chan uint64, concurrency*10)
+	go func() {
+		tempClient, err := client.New(location)
+		if err != nil {
+			slog.Error("Coordinator: failed to create client", "error", err)
+			os.Exit(1)
+		}
+
+		var startingBlock *types.Block
+		if startBlock == -1 {
+			startingBlock, err = tempClient.BlockByNumber(context.Background(), nil)
+		} else {
+			startingBlock, err = tempClient.BlockByNumber(context.Background(), big.NewInt(startBlock))
+		}
+		tempClient.Close()
+
+		if err != nil {
+			slog.Error("Coordinator: failed to get starting block", "error", err)
+			os.Exit(1)
+		}
+
+		startBlockNum := startingBlock.NumberU64()
+		slog.Info("Starting from block", "block", startBlockNum)
+		startBlockNumChan <- startBlockNum
+
+		for blockNum := startBlockNum; blockNum > endBlock; blockNum-- {
+			blockNumChan <- blockNum
+		}
+		close(blockNumChan)
+	}()
+
+	for i := 0; i < concurrency; i++ {
+		fetcherWg.Add(1)
+		go func(fetcherID int) {
+			defer fetcherWg.Done()
+			logger := slog.With("fetcher_id", fetcherID)
+
+			fetcherClient, err := client.New(location)
+			if err != nil {
+				logger.Warn("Fetcher: failed to create client", "error", err)
+				return
+			}
+			defer fetcherClient.Close()
+
+			for blockNum := range blockNumChan {
+				header, err := fetchHeaderWithRetry(logger, fetcherClient, blockNum, retries, backoff)
+				if err != nil {
+					logger.Warn("Fetcher: failed to fetch header, giving up on block", "block", blockNum, "error", err)
+					jobChan <- blockJob{Number: blockNum, Failed: true}
+					continue
+				}
+
+				// Ancients-first: skip the body fetch entirely for blocks
+				// with no transactions at all (the geth downloader uses the
+				// same EmptyRootHash/EmptyUncleHash check to decide whether
+				// a body request is worth making).
+				if header.TxHash == types.EmptyRootHash && header.UncleHash == types.EmptyUncleHash {
+					jobChan <- blockJob{Number: blockNum, Header: header}
+					continue
+				}
+
+				block, err := fetchBlockWithRetry(logger, fetcherClient, blockNum, retries, backoff)
+				if err != nil {
+					logger.Warn("Fetcher: failed to fetch block, giving up on block", "block", blockNum, "error", err)
+					jobChan <- blockJob{Number: blockNum, Failed: true}
+					continue
+				}
+
+				jobChan <- blockJob{Number: blockNum, Header: header, Block: block}
+			}
+		}(i)
+	}
+
+	go func() {
+		fetcherWg.Wait()
+		close(jobChan)
+	}()
+
+	wg.Wait()
+	close(resultChan)
+
+	<-writerDone
+
+	block, offset := progress.get()
+	if err := writeCheckpoint(checkpointPath, state.checkpointAt(block, offset)); err != nil {
+		slog.Error("Failed to write final checkpoint", "error", err)
+	}
+
+	slog.Info("All processing complete")
+	return nil
+}
+
+// fetchHeaderWithRetry calls HeaderByNumber once and then retries it up to
+// maxRetries more times until it succeeds. A nil header with a nil error (LocalClient.HeaderByNumber's
+// way of reporting "no such block", e.g. a non-canonical or not-yet-written
+// number) is treated the same as a transport error: a retryable miss, never
+// returned to the caller, since callers dereference the header unconditionally.
+func fetchHeaderWithRetry(logger *slog.Logger, c client.Client, blockNum uint64, maxRetries int, backoff time.Duration) (*types.Header, error) {
+	var header *types.Header
+	var err error
+	retryDelay := backoff
+
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		header, err = c.HeaderByNumber(context.Background(), big.NewInt(int64(blockNum)))
+		if err == nil && header != nil {
+			return header, nil
+		}
+		if err == nil {
+			err = fmt.Errorf("block %d not found", blockNum)
+		}
+		logger.Debug("Fetcher: header fetch attempt failed", "block", blockNum, "attempt", attempt+1, "max_retries", maxRetries, "error", err)
+		if attempt < maxRetries {
+			time.Sleep(retryDelay)
+			retryDelay *= 2
+		}
+	}
+	return nil, err
+}
+
+// fetchBlockWithRetry is fetchHeaderWithRetry's body-fetch counterpart; see
+// its doc comment for why a nil block with a nil error is treated as a miss.
+func fetchBlockWithRetry(logger *slog.Logger, c client.Client, blockNum uint64, maxRetries int, backoff time.Duration) (*types.Block, error) {
+	var block *types.Block
+	var err error
+	retryDelay := backoff
+
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		block, err = c.BlockByNumber(context.Background(), big.NewInt(int64(blockNum)))
+		if err == nil && block != nil {
+			return block, nil
+		}
+		if err == nil {
+			err = fmt.Errorf("block %d not found", blockNum)
+		}
+		logger.Debug("Fetcher: block fetch attempt failed", "block", blockNum, "attempt", attempt+1, "max_retries", maxRetries, "error", err)
+		if attempt < maxRetries {
+			time.Sleep(retryDelay)
+			retryDelay *= 2
+		}
+	}
+	return nil, err
+}
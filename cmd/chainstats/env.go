@@ -0,0 +1,30 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"strconv"
+)
+
+// envOrDefault mirrors the old tools' "flag falls back to env var falls
+// back to hardcoded default" behavior, so existing .env-based deployments
+// keep working under the new flags.
+func envOrDefault(key, def string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return def
+}
+
+func envOrDefaultInt(key string, def int) int {
+	val := os.Getenv(key)
+	if val == "" {
+		return def
+	}
+	n, err := strconv.Atoi(val)
+	if err != nil {
+		slog.Warn("Invalid env value, using default", "key", key, "value", val, "default", def)
+		return def
+	}
+	return n
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/spf13/cobra"
+
+	"github.com/ClaudeZsb/toolkit/internal/feemath"
+	"github.com/ClaudeZsb/toolkit/internal/output"
+)
+
+var gasmonColumns = []string{"timestamp", "block_number", "max_priority_fee_gwei", "gas_usage_ratio", "base_fee_gwei", "next_base_fee_gwei", "blob_base_fee_gwei", "blob_gas_used", "reorg_depth"}
+
+func newGasmonCmd() *cobra.Command {
+	var (
+		outputFile string
+		interval   int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "gasmon",
+		Short: "Monitor live gas prices and usage, appending samples to a CSV or JSONL file",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rpcURL, err := cmd.Flags().GetString("rpc")
+			if err != nil {
+				return err
+			}
+			format, err := cmd.Flags().GetString("output-format")
+			if err != nil {
+				return err
+			}
+			outputFormat, err := output.ParseFormat(format)
+			if err != nil {
+				return err
+			}
+			return runGasmon(rpcURL, outputFile, interval, outputFormat)
+		},
+	}
+
+	cmd.Flags().StringVar(&outputFile, "output", "fees.csv", "Output file path")
+	cmd.Flags().IntVar(&interval, "interval", 1, "Polling interval in seconds (ignored for ws/wss RPC URLs)")
+
+	return cmd
+}
+
+func runGasmon(rpcURL, outputFile string, interval int, format output.Format) error {
+	file, err := os.OpenFile(outputFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open output file: %w", err)
+	}
+	defer file.Close()
+
+	ethClient, err := ethclient.Dial(rpcURL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to RPC: %w", err)
+	}
+	defer ethClient.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to get file info: %w", err)
+	}
+	writer, err := output.NewRowWriter(file, format, gasmonColumns, fileInfo.Size() == 0)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Starting monitoring", "rpc_url", rpcURL, "output_file", outputFile)
+
+	mon := &gasMonitor{ethClient: ethClient, writer: writer}
+
+	if strings.HasPrefix(rpcURL, "ws://") || strings.HasPrefix(rpcURL, "wss://") {
+		slog.Info("Subscribing to newHeads (reorg-aware)")
+		return mon.runSubscribe(context.Background())
+	}
+
+	slog.Info("Polling for new blocks", "interval_seconds", interval)
+	return mon.runPoll(context.Background(), interval)
+}
+
+// gasMonitor tracks the previously observed head so it can detect reorgs
+// across both the subscription and polling code paths.
+type gasMonitor struct {
+	ethClient *ethclient.Client
+	writer    *output.RowWriter
+
+	lastHead *types.Header
+}
+
+func (m *gasMonitor) runSubscribe(ctx context.Context) error {
+	headChan := make(chan *types.Header)
+	sub, err := m.ethClient.SubscribeNewHead(ctx, headChan)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to new heads: %w", err)
+	}
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case err := <-sub.Err():
+			return fmt.Errorf("newHeads subscription error: %w", err)
+		case header := <-headChan:
+			m.sample(ctx, header)
+		}
+	}
+}
+
+func (m *gasMonitor) runPoll(ctx context.Context, interval int) error {
+	var lastBlockNumber uint64
+
+	for {
+		blockNumber, err := m.ethClient.BlockNumber(ctx)
+		if err != nil {
+			slog.Warn("Failed to get block number", "error", err)
+			time.Sleep(time.Duration(interval) * time.Second)
+			continue
+		}
+
+		if blockNumber == lastBlockNumber {
+			time.Sleep(time.Duration(interval) * time.Second)
+			continue
+		}
+		lastBlockNumber = blockNumber
+
+		header, err := m.ethClient.HeaderByNumber(ctx, new(big.Int).SetUint64(blockNumber))
+		if err != nil {
+			slog.Warn("Failed to get block header", "error", err)
+			time.Sleep(time.Duration(interval) * time.Second)
+			continue
+		}
+
+		m.sample(ctx, header)
+		time.Sleep(time.Duration(interval) * time.Second)
+	}
+}
+
+// sample computes gas/fee stats for header, detects reorgs against the
+// previously observed head, and appends a row to the output file.
+func (m *gasMonitor) sample(ctx context.Context, header *types.Header) {
+	reorgDepth := 0
+	if m.lastHead != nil && header.ParentHash != m.lastHead.Hash() {
+		// A parent-hash mismatch alone doesn't mean a reorg: in polling mode
+		// the head can legitimately advance by more than one block between
+		// samples, so header's parent is simply a block we never saw, not an
+		// orphan of our previous head. reorgDepth confirms whether lastHead
+		// is actually missing from header's ancestry before we call it one.
+		if depth, isReorg := m.reorgDepth(ctx, header); isReorg {
+			reorgDepth = depth
+			slog.Warn("Reorg detected",
+				"new_head", header.Number.Uint64(), "new_hash", header.Hash(),
+				"prev_head", m.lastHead.Number.Uint64(), "prev_hash", m.lastHead.Hash(), "depth", reorgDepth)
+		}
+	}
+	m.lastHead = header
+
+	maxPriorityFee, err := m.ethClient.SuggestGasTipCap(ctx)
+	if err != nil {
+		slog.Warn("Failed to get max priority fee", "error", err)
+		return
+	}
+
+	gasUsageRatio := float64(header.GasUsed) / float64(header.GasLimit)
+	maxPriorityFeeGwei := float64(maxPriorityFee.Int64()) / 1e9
+
+	baseFeeGwei := 0.0
+	nextBaseFeeGwei := 0.0
+	if header.BaseFee != nil {
+		baseFeeGwei = weiToGwei(header.BaseFee)
+		nextBaseFee := feemath.CalcNextBaseFee(header.GasUsed, header.GasLimit, header.BaseFee)
+		nextBaseFeeGwei = weiToGwei(nextBaseFee)
+	}
+
+	blobBaseFeeGwei := 0.0
+	blobGasUsed := uint64(0)
+	if header.ExcessBlobGas != nil {
+		blobBaseFeeGwei = weiToGwei(feemath.CalcBlobBaseFee(*header.ExcessBlobGas))
+	}
+	if header.BlobGasUsed != nil {
+		blobGasUsed = *header.BlobGasUsed
+	}
+
+	row := []string{
+		time.Now().Format(time.RFC3339),
+		strconv.FormatUint(header.Number.Uint64(), 10),
+		strconv.FormatFloat(maxPriorityFeeGwei, 'f', 9, 64),
+		strconv.FormatFloat(gasUsageRatio, 'f', 6, 64),
+		strconv.FormatFloat(baseFeeGwei, 'f', 9, 64),
+		strconv.FormatFloat(nextBaseFeeGwei, 'f', 9, 64),
+		strconv.FormatFloat(blobBaseFeeGwei, 'f', 9, 64),
+		strconv.FormatUint(blobGasUsed, 10),
+		strconv.Itoa(reorgDepth),
+	}
+	if err := m.writer.WriteRow(row); err != nil {
+		slog.Warn("Failed to write to file", "error", err)
+		return
+	}
+	if err := m.writer.Flush(); err != nil {
+		slog.Warn("Failed to flush output", "error", err)
+	}
+
+	slog.Info("Block sampled",
+		"block", header.Number.Uint64(), "base_fee_gwei", baseFeeGwei, "max_priority_fee_gwei", maxPriorityFeeGwei,
+		"gas_usage_pct", gasUsageRatio*100, "reorg_depth", reorgDepth)
+}
+
+// reorgDepth walks back from the new head's parent chain, over RPC, until it
+// reaches lastHead's height, and reports whether lastHead is actually on
+// that ancestry (no reorg, just a gap between polls) or was orphaned (a
+// genuine reorg). The reported depth is lastHead's height minus the height
+// where the walk stopped, plus one; the previous chain is not walked back to
+// the common ancestor, so orphaned blocks below that height are not counted.
+// Capped at maxReorgWalk so a bug can't turn this into an unbounded crawl
+// back to genesis; a walk that hits the cap or fails to fetch a parent is
+// reported conservatively as a (depth-1) reorg rather than silently ignored.
+func (m *gasMonitor) reorgDepth(ctx context.Context, newHead *types.Header) (depth int, isReorg bool) {
+	const maxReorgWalk = 64
+
+	prevNumber := m.lastHead.Number.Uint64()
+	prevHash := m.lastHead.Hash()
+	cursor := newHead
+	for i := 0; i < maxReorgWalk; i++ {
+		if cursor.Number.Uint64() <= prevNumber {
+			if cursor.Number.Uint64() == prevNumber && cursor.Hash() == prevHash {
+				return 0, false
+			}
+			return int(prevNumber-cursor.Number.Uint64()) + 1, true
+		}
+		parent, err := m.ethClient.HeaderByHash(ctx, cursor.ParentHash)
+		if err != nil || parent == nil {
+			break
+		}
+		cursor = parent
+	}
+	return 1, true
+}
+
+func weiToGwei(wei *big.Int) float64 {
+	f, _ := new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(1e9)).Float64()
+	return f
+}
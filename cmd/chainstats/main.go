@@ -0,0 +1,92 @@
+// Command chainstats collects and analyzes Ethereum chain data: recent
+// block history, L1 compression cost estimates, and live gas conditions.
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
+)
+
+func main() {
+	// Flag defaults below read env vars via envOrDefault/envOrDefaultInt at
+	// registration time, which is before cobra parses flags but after
+	// main() runs — so .env must be loaded here, before PersistentFlags()
+	// binds its defaults, not in PersistentPreRunE (which only runs after
+	// flags are already parsed and its defaults already locked in).
+	if err := godotenv.Load(); err != nil {
+		slog.Warn("No .env file found, using default values")
+	}
+
+	var (
+		rpcURL       string
+		datadir      string
+		concurrency  int
+		retries      int
+		backoff      time.Duration
+		outputFormat string
+		logFormat    string
+		logLevel     string
+	)
+
+	root := &cobra.Command{
+		Use:           "chainstats",
+		Short:         "Chain data collection and analysis utilities",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return configureLogger(logFormat, logLevel)
+		},
+	}
+
+	// Shared across every subcommand, so a single --rpc/--datadir/
+	// --concurrency/--retries/--backoff/--output-format means the same
+	// thing everywhere instead of each subcommand inventing its own names
+	// and defaults.
+	root.PersistentFlags().StringVar(&rpcURL, "rpc", envOrDefault("RPC_URL", "https://rpc.ankr.com/eth"), "RPC endpoint URL")
+	root.PersistentFlags().StringVar(&datadir, "datadir", "", "path to a local geth chaindata directory; when set, blocks are read directly with no RPC calls and --rpc is ignored")
+	root.PersistentFlags().IntVar(&concurrency, "concurrency", envOrDefaultInt("CONCURRENCY", 20), "maximum concurrent fetches in flight")
+	root.PersistentFlags().IntVar(&retries, "retries", envOrDefaultInt("RETRIES", 8), "retries after a failed fetch before giving up on a block")
+	root.PersistentFlags().DurationVar(&backoff, "backoff", 100*time.Millisecond, "initial delay between retries, doubled after each attempt")
+	root.PersistentFlags().StringVar(&outputFormat, "output-format", "csv", "row output format: csv or jsonl (parquet is not implemented yet)")
+	root.PersistentFlags().StringVar(&logFormat, "log-format", envOrDefault("LOG_FORMAT", "text"), "log output format: text, json, or logfmt")
+	root.PersistentFlags().StringVar(&logLevel, "log-level", envOrDefault("LOG_LEVEL", "info"), "minimum log level: debug, info, warn, or error")
+
+	root.AddCommand(newBlocksCmd())
+	root.AddCommand(newCompressionCmd())
+	root.AddCommand(newGasmonCmd())
+
+	if err := root.Execute(); err != nil {
+		slog.Error("chainstats failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+// configureLogger installs a slog default logger matching --log-format and
+// --log-level, so every subcommand's logging is controllable the same way.
+// logfmt has no stdlib handler, but slog.TextHandler already produces
+// logfmt-compatible output, so it's treated as an alias for "text".
+func configureLogger(format, level string) error {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("invalid --log-level %q: %w", level, err)
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl}
+	var handler slog.Handler
+	switch format {
+	case "json":
+		handler = slog.NewJSONHandler(os.Stderr, opts)
+	case "text", "logfmt":
+		handler = slog.NewTextHandler(os.Stderr, opts)
+	default:
+		return fmt.Errorf("unknown --log-format %q (want text, json, or logfmt)", format)
+	}
+
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
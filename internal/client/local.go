@@ -0,0 +1,89 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/node"
+)
+
+// LocalClient reads blocks directly out of a geth chaindata database,
+// letting callers scan an archive node's history without going through
+// HTTP at all.
+type LocalClient struct {
+	n  *node.Node
+	db ethdb.Database
+}
+
+// NewLocalClient opens the chaindata database under dataDir (a geth
+// --datadir path).
+func NewLocalClient(dataDir string) (*LocalClient, error) {
+	nodeCfg := node.DefaultConfig
+	nodeCfg.Name = "geth"
+	nodeCfg.DataDir = dataDir
+	n, err := node.New(&nodeCfg)
+	if err != nil {
+		return nil, err
+	}
+	handles := utils.MakeDatabaseHandles(1024)
+	db, err := n.OpenDatabaseWithFreezer("chaindata", 512, handles, "", "", true)
+	if err != nil {
+		return nil, err
+	}
+	return &LocalClient{
+		n:  n,
+		db: db,
+	}, nil
+}
+
+func (c *LocalClient) Close() {
+	_ = c.db.Close()
+	_ = c.n.Close()
+}
+
+func (c *LocalClient) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	number := rawdb.ReadHeaderNumber(c.db, hash)
+	if number == nil {
+		return nil, nil
+	}
+	return rawdb.ReadBlock(c.db, hash, *number), nil
+}
+
+func (c *LocalClient) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	if number == nil || number.Int64() < 0 {
+		return c.BlockByHash(ctx, rawdb.ReadHeadBlockHash(c.db))
+	}
+	hash := rawdb.ReadCanonicalHash(c.db, number.Uint64())
+	if bytes.Equal(hash.Bytes(), common.Hash{}.Bytes()) {
+		return nil, nil
+	}
+	return rawdb.ReadBlock(c.db, hash, number.Uint64()), nil
+}
+
+// HeaderByNumber reads just the header, skipping the body entirely, so
+// callers that only need to decide whether a block is worth a body fetch
+// (e.g. an empty-block filter) avoid paying for the transactions list.
+func (c *LocalClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if number == nil || number.Int64() < 0 {
+		return c.HeaderByHash(ctx, rawdb.ReadHeadBlockHash(c.db))
+	}
+	hash := rawdb.ReadCanonicalHash(c.db, number.Uint64())
+	if bytes.Equal(hash.Bytes(), common.Hash{}.Bytes()) {
+		return nil, nil
+	}
+	return rawdb.ReadHeader(c.db, hash, number.Uint64()), nil
+}
+
+func (c *LocalClient) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	number := rawdb.ReadHeaderNumber(c.db, hash)
+	if number == nil {
+		return nil, nil
+	}
+	return rawdb.ReadHeader(c.db, hash, *number), nil
+}
@@ -0,0 +1,194 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+)
+
+// BlockData is the subset of block fields the blocks subcommand works with.
+// GetBlockInfo and GetBlocksBatch fill every field over raw JSON-RPC;
+// BlockDataFromBlock fills only Number, GasUsed, GasLimit, Timestamp and Hash
+// from a block read through the Client abstraction.
+type BlockData struct {
+	Number        uint64
+	GasUsed       uint64
+	GasLimit      uint64
+	Timestamp     uint64
+	Hash          string
+	ParentHash    string
+	BaseFee       uint64  // wei; zero pre-London
+	ExcessBlobGas *uint64 // nil pre-Cancun
+	BlobGasUsed   *uint64 // nil pre-Cancun
+}
+
+type blockRPCResponseBody struct {
+	Number        string `json:"number"`
+	GasUsed       string `json:"gasUsed"`
+	GasLimit      string `json:"gasLimit"`
+	Timestamp     string `json:"timestamp"`
+	Hash          string `json:"hash"`
+	ParentHash    string `json:"parentHash"`
+	BaseFeePerGas string `json:"baseFeePerGas"`
+	ExcessBlobGas string `json:"excessBlobGas"`
+	BlobGasUsed   string `json:"blobGasUsed"`
+}
+
+type blockRPCResponse struct {
+	Result blockRPCResponseBody `json:"result"`
+}
+
+var rpcHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// GetBlockInfo fetches a single block via eth_getBlockByNumber and extracts
+// the fields callers need, without decoding a full *types.Block.
+func GetBlockInfo(rpcURL string, blockNumber uint64) (*BlockData, error) {
+	requestBody := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "eth_getBlockByNumber",
+		"params":  []interface{}{fmt.Sprintf("0x%x", blockNumber), false},
+		"id":      1,
+	}
+
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %v", err)
+	}
+
+	resp, err := rpcHTTPClient.Post(rpcURL, "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var blockResp blockRPCResponse
+	if err := json.NewDecoder(resp.Body).Decode(&blockResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %v", err)
+	}
+
+	if blockResp.Result.Number == "" {
+		return nil, fmt.Errorf("block not found")
+	}
+
+	return blockDataFromRPC(blockResp.Result), nil
+}
+
+func blockDataFromRPC(body blockRPCResponseBody) *BlockData {
+	return &BlockData{
+		Number:        hexToUint64(body.Number),
+		GasUsed:       hexToUint64(body.GasUsed),
+		GasLimit:      hexToUint64(body.GasLimit),
+		Timestamp:     hexToUint64(body.Timestamp),
+		Hash:          body.Hash,
+		ParentHash:    body.ParentHash,
+		BaseFee:       hexToUint64(body.BaseFeePerGas),
+		ExcessBlobGas: hexToUint64Ptr(body.ExcessBlobGas),
+		BlobGasUsed:   hexToUint64Ptr(body.BlobGasUsed),
+	}
+}
+
+// BlockResult is the outcome of fetching a single block as part of a batch:
+// either Data is populated, or Error explains why it wasn't.
+type BlockResult struct {
+	BlockNumber uint64
+	Data        *BlockData
+	Error       error
+}
+
+type batchRPCRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+	ID      int           `json:"id"`
+}
+
+type batchRPCResponse struct {
+	ID     int                  `json:"id"`
+	Error  *rpcErrorObj         `json:"error"`
+	Result blockRPCResponseBody `json:"result"`
+}
+
+type rpcErrorObj struct {
+	Message string `json:"message"`
+}
+
+// GetBlocksBatch packs eth_getBlockByNumber calls for blockNumbers into a
+// single JSON-RPC batch request, matching response ids back to the
+// requested block numbers. It returns one BlockResult per input block
+// number, in the same order; an error is only returned if the batch
+// request failed transport-wise (e.g. the whole HTTP call errored).
+func GetBlocksBatch(rpcURL string, blockNumbers []uint64) ([]BlockResult, error) {
+	requests := make([]batchRPCRequest, len(blockNumbers))
+	for i, blockNumber := range blockNumbers {
+		requests[i] = batchRPCRequest{
+			JSONRPC: "2.0",
+			Method:  "eth_getBlockByNumber",
+			Params:  []interface{}{fmt.Sprintf("0x%x", blockNumber), false},
+			ID:      i,
+		}
+	}
+
+	jsonData, err := json.Marshal(requests)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal batch request: %v", err)
+	}
+
+	resp, err := rpcHTTPClient.Post(rpcURL, "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to make batch request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var batchResp []batchRPCResponse
+	if err := json.NewDecoder(resp.Body).Decode(&batchResp); err != nil {
+		return nil, fmt.Errorf("failed to decode batch response: %v", err)
+	}
+
+	results := make([]BlockResult, len(blockNumbers))
+	for i, blockNumber := range blockNumbers {
+		results[i] = BlockResult{BlockNumber: blockNumber, Error: fmt.Errorf("missing result for block %d", blockNumber)}
+	}
+
+	for _, item := range batchResp {
+		if item.ID < 0 || item.ID >= len(blockNumbers) {
+			continue
+		}
+		blockNumber := blockNumbers[item.ID]
+		if item.Error != nil {
+			results[item.ID] = BlockResult{BlockNumber: blockNumber, Error: fmt.Errorf("rpc error: %s", item.Error.Message)}
+			continue
+		}
+		if item.Result.Number == "" {
+			results[item.ID] = BlockResult{BlockNumber: blockNumber, Error: fmt.Errorf("block not found")}
+			continue
+		}
+		results[item.ID] = BlockResult{BlockNumber: blockNumber, Data: blockDataFromRPC(item.Result)}
+	}
+
+	return results, nil
+}
+
+func hexToUint64(hex string) uint64 {
+	if len(hex) < 3 {
+		return 0
+	}
+	n, _ := new(big.Int).SetString(hex[2:], 16)
+	if n == nil {
+		return 0
+	}
+	return n.Uint64()
+}
+
+// hexToUint64Ptr is like hexToUint64 but returns nil for fields the RPC
+// server omits entirely (e.g. blob fields on pre-Cancun blocks), so callers
+// can distinguish "absent" from "zero".
+func hexToUint64Ptr(hex string) *uint64 {
+	if hex == "" {
+		return nil
+	}
+	v := hexToUint64(hex)
+	return &v
+}
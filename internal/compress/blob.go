@@ -0,0 +1,50 @@
+package compress
+
+// BlobFieldElements and BlobBytesPerFieldElement define a blob's usable
+// capacity under EIP-4844: 4096 field elements of 31 usable bytes each (the
+// top byte of each 32-byte element is reserved to keep it a valid BLS
+// scalar).
+const (
+	BlobFieldElements        = 4096
+	BlobBytesPerFieldElement = 31
+	BlobUsableCapacity       = BlobFieldElements * BlobBytesPerFieldElement
+)
+
+// BlobFillTracker simulates packing a sequence of channel frames into
+// fixed-capacity blobs, the way the OP/Base batcher does, so callers can
+// report each tx's cumulative fill and how many blob boundaries it crossed.
+// This is a simplified RLP -> zlib -> blob-fill model, not the real
+// channel/frame/span-batch encoding.
+type BlobFillTracker struct {
+	cumulative uint32
+}
+
+// RestoreBlobFillTracker rebuilds a tracker at the given fill level, for
+// resuming from a checkpoint.
+func RestoreBlobFillTracker(cumulative uint32) *BlobFillTracker {
+	return &BlobFillTracker{cumulative: cumulative}
+}
+
+// Checkpoint returns the tracker's current fill level.
+func (t *BlobFillTracker) Checkpoint() uint32 {
+	return t.cumulative
+}
+
+// Add records compressedBytes as the next contribution to the blob stream
+// and returns the resulting fill level of the (now current) blob, plus how
+// many blob boundaries were crossed while packing it.
+func (t *BlobFillTracker) Add(compressedBytes uint32) (cumulativeFill uint32, boundaryCrossings int) {
+	remaining := compressedBytes
+	for remaining > 0 {
+		space := uint32(BlobUsableCapacity) - t.cumulative
+		if remaining <= space {
+			t.cumulative += remaining
+			remaining = 0
+		} else {
+			remaining -= space
+			t.cumulative = 0
+			boundaryCrossings++
+		}
+	}
+	return t.cumulative, boundaryCrossings
+}
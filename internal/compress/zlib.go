@@ -0,0 +1,121 @@
+// Package compress holds the calldata compression estimators used by the
+// chainstats compression subcommand.
+package compress
+
+import (
+	"bytes"
+	"compress/zlib"
+	"log/slog"
+	"os"
+)
+
+// historyCap bounds the raw (pre-compression) input window kept for
+// Checkpoint, comfortably larger than the 128kb *compressed* rotation
+// window in Write since tx calldata rarely compresses below 50%.
+const historyCap = 512 * 1024
+
+// ZlibBatchEstimator simulates a zlib compressor at max compression that
+// works on (large) tx batches. Should bootstrap it before use by calling it
+// on several samples of representative data.
+type ZlibBatchEstimator struct {
+	b [2]bytes.Buffer
+	w [2]*zlib.Writer
+
+	// history holds the raw bytes fed to Write, capped at historyCap, so
+	// Checkpoint can snapshot enough input to rebuild a closely matching
+	// estimator with RestoreZlibBatchEstimator. compress/zlib exposes no
+	// way to export a Writer's internal dictionary directly, so this
+	// reconstructs it indirectly by replaying the input.
+	history []byte
+}
+
+func NewZlibBatchEstimator() *ZlibBatchEstimator {
+	b := &ZlibBatchEstimator{}
+	var err error
+	for i := range b.w {
+		b.w[i], err = zlib.NewWriterLevel(&b.b[i], zlib.BestCompression)
+		if err != nil {
+			slog.Error("zlib compressor failed", "error", err)
+			os.Exit(1)
+		}
+	}
+	return b
+}
+
+// replayChunkSize is the write size RestoreZlibBatchEstimator replays history
+// in, so the rotating buffers fill and rotate as they do under per-tx writes.
+const replayChunkSize = 1024
+
+// RestoreZlibBatchEstimator rebuilds an estimator close to the one Checkpoint
+// was called on, by replaying the captured input history through a freshly
+// constructed estimator in replayChunkSize writes. The replay splits the
+// input at different points than the original writes and covers at most
+// historyCap bytes, so the rebuilt buffers and estimates are not identical.
+func RestoreZlibBatchEstimator(history []byte) *ZlibBatchEstimator {
+	w := NewZlibBatchEstimator()
+	for len(history) > 0 {
+		n := min(len(history), replayChunkSize)
+		w.Write(history[:n])
+		history = history[n:]
+	}
+	return w
+}
+
+// Checkpoint returns a copy of the raw input fed to Write so far (bounded by
+// historyCap), suitable for RestoreZlibBatchEstimator.
+func (w *ZlibBatchEstimator) Checkpoint() []byte {
+	out := make([]byte, len(w.history))
+	copy(out, w.history)
+	return out
+}
+
+func (w *ZlibBatchEstimator) Write(p []byte) uint32 {
+	// targeting:
+	//	b[0] == 0-64kb
+	//	b[1] == 64kb-128kb
+	before := w.b[1].Len()
+	_, err := w.w[1].Write(p)
+	if err != nil {
+		slog.Error("zlib compressor failed", "error", err)
+		os.Exit(1)
+	}
+	err = w.w[1].Flush()
+	if err != nil {
+		slog.Error("zlib compressor failed", "error", err)
+		os.Exit(1)
+	}
+	after := w.b[1].Len()
+	// if b[1] > 64kb, write to b[0]
+	if w.b[1].Len() > 64*1024 {
+		_, err = w.w[0].Write(p)
+		if err != nil {
+			slog.Error("zlib compressor failed", "error", err)
+			os.Exit(1)
+		}
+		err = w.w[0].Flush()
+		if err != nil {
+			slog.Error("zlib compressor failed", "error", err)
+			os.Exit(1)
+		}
+	}
+	// if b[1] > 128kb, rotate
+	if w.b[1].Len() > 128*1024 {
+		w.b[1].Reset()
+		w.w[1].Reset(&w.b[1])
+		tb := w.b[1]
+		tw := w.w[1]
+		w.b[1] = w.b[0]
+		w.w[1] = w.w[0]
+		w.b[0] = tb
+		w.w[0] = tw
+	}
+	w.history = append(w.history, p...)
+	if len(w.history) > historyCap {
+		w.history = append([]byte(nil), w.history[len(w.history)-historyCap:]...)
+	}
+
+	if after-before-2 < 0 {
+		return 0
+	}
+	return uint32(after - before - 2) // flush writes 2 extra "sync" bytes so don't count those
+}
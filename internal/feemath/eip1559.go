@@ -0,0 +1,70 @@
+// Package feemath implements the EIP-1559 and EIP-4844 fee formulas needed
+// to project the next block's base fee and the current blob base fee from
+// header fields, without depending on a full chain config.
+package feemath
+
+import "math/big"
+
+const (
+	baseFeeChangeDenominator = 8
+	elasticityMultiplier     = 2
+
+	minBlobBaseFee            = 1
+	blobBaseFeeUpdateFraction = 3338477 // post-Cancun BLOB_BASE_FEE_UPDATE_FRACTION
+)
+
+// CalcNextBaseFee predicts the base fee of the block following one with the
+// given gasUsed, gasLimit and baseFee, using the standard EIP-1559 formula.
+func CalcNextBaseFee(gasUsed, gasLimit uint64, baseFee *big.Int) *big.Int {
+	if baseFee == nil {
+		return nil
+	}
+
+	gasTarget := gasLimit / elasticityMultiplier
+	if gasTarget == 0 {
+		return new(big.Int).Set(baseFee)
+	}
+
+	if gasUsed == gasTarget {
+		return new(big.Int).Set(baseFee)
+	}
+
+	if gasUsed > gasTarget {
+		gasUsedDelta := gasUsed - gasTarget
+		x := new(big.Int).Mul(baseFee, new(big.Int).SetUint64(gasUsedDelta))
+		y := x.Div(x, new(big.Int).SetUint64(gasTarget))
+		baseFeeDelta := bigMax(y.Div(y, big.NewInt(baseFeeChangeDenominator)), big.NewInt(1))
+		return new(big.Int).Add(baseFee, baseFeeDelta)
+	}
+
+	gasUsedDelta := gasTarget - gasUsed
+	x := new(big.Int).Mul(baseFee, new(big.Int).SetUint64(gasUsedDelta))
+	y := x.Div(x, new(big.Int).SetUint64(gasTarget))
+	baseFeeDelta := y.Div(y, big.NewInt(baseFeeChangeDenominator))
+	return bigMax(new(big.Int).Sub(baseFee, baseFeeDelta), big.NewInt(0))
+}
+
+// CalcBlobBaseFee derives the blob base fee from a header's excess blob gas,
+// per EIP-4844's fake-exponential formula.
+func CalcBlobBaseFee(excessBlobGas uint64) *big.Int {
+	return fakeExponential(big.NewInt(minBlobBaseFee), new(big.Int).SetUint64(excessBlobGas), big.NewInt(blobBaseFeeUpdateFraction))
+}
+
+func fakeExponential(factor, numerator, denominator *big.Int) *big.Int {
+	output := new(big.Int)
+	numeratorAccum := new(big.Int).Mul(factor, denominator)
+	for i := 1; numeratorAccum.Sign() > 0; i++ {
+		output.Add(output, numeratorAccum)
+		numeratorAccum.Mul(numeratorAccum, numerator)
+		numeratorAccum.Div(numeratorAccum, denominator)
+		numeratorAccum.Div(numeratorAccum, big.NewInt(int64(i)))
+	}
+	return output.Div(output, denominator)
+}
+
+func bigMax(a, b *big.Int) *big.Int {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
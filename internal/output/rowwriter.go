@@ -0,0 +1,100 @@
+// Package output provides the row-oriented output formats shared by the
+// chainstats subcommands, so "--output-format" means the same thing and is
+// implemented once regardless of which subcommand produced the rows.
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Format is a supported --output-format value.
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatJSONL   Format = "jsonl"
+	FormatParquet Format = "parquet"
+)
+
+// ParseFormat validates a --output-format flag value.
+func ParseFormat(s string) (Format, error) {
+	switch Format(s) {
+	case FormatCSV, FormatJSONL:
+		return Format(s), nil
+	case FormatParquet:
+		return "", fmt.Errorf("output format %q is not implemented yet", s)
+	default:
+		return "", fmt.Errorf("unknown output format %q (want csv, jsonl, or parquet)", s)
+	}
+}
+
+// Ext returns the file extension conventionally used for format.
+func (f Format) Ext() string {
+	return string(f)
+}
+
+// RowWriter writes a stream of same-shaped rows as CSV or JSONL, so callers
+// write rows once and let --output-format pick the encoding.
+type RowWriter struct {
+	format Format
+	header []string
+	w      io.Writer
+	csv    *csv.Writer
+}
+
+// NewRowWriter returns a RowWriter for format, writing to w. header names
+// each column and, for CSV, is written immediately as the header line unless
+// writeHeader is false (e.g. when appending to a file that already has one).
+func NewRowWriter(w io.Writer, format Format, header []string, writeHeader bool) (*RowWriter, error) {
+	rw := &RowWriter{format: format, header: header, w: w}
+	switch format {
+	case FormatCSV:
+		rw.csv = csv.NewWriter(w)
+		if writeHeader {
+			if err := rw.csv.Write(header); err != nil {
+				return nil, fmt.Errorf("write csv header: %w", err)
+			}
+		}
+	case FormatJSONL:
+		// No header row: each line is self-describing.
+	default:
+		return nil, fmt.Errorf("unsupported output format %q", format)
+	}
+	return rw, nil
+}
+
+// WriteRow writes one row. values must align positionally with header.
+func (rw *RowWriter) WriteRow(values []string) error {
+	switch rw.format {
+	case FormatCSV:
+		return rw.csv.Write(values)
+	case FormatJSONL:
+		obj := make(map[string]string, len(rw.header))
+		for i, h := range rw.header {
+			if i < len(values) {
+				obj[h] = values[i]
+			}
+		}
+		data, err := json.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		data = append(data, '\n')
+		_, err = rw.w.Write(data)
+		return err
+	default:
+		return fmt.Errorf("unsupported output format %q", rw.format)
+	}
+}
+
+// Flush flushes any buffered output. Safe to call for every format.
+func (rw *RowWriter) Flush() error {
+	if rw.csv != nil {
+		rw.csv.Flush()
+		return rw.csv.Error()
+	}
+	return nil
+}